@@ -0,0 +1,44 @@
+package assets
+
+import (
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+// PublicAssetAPI exposes the asset registry over RPC, so operators can
+// register, list and disable source/destination token pairs without
+// restarting the node.
+type PublicAssetAPI struct {
+	store *AssetStore
+}
+
+// NewPublicAssetAPI creates the RPC API backed by store.
+func NewPublicAssetAPI(store *AssetStore) *PublicAssetAPI {
+	return &PublicAssetAPI{store: store}
+}
+
+// RegisterAsset registers or updates a source/destination asset pair.
+func (api *PublicAssetAPI) RegisterAsset(sourceChainID uint64, sourceAssetAddr common.Address,
+	destChainID uint64, destAssetAddr common.Address, decimals uint8, symbol string) (common.Hash, error) {
+	asset := &Asset{
+		SourceChainID:   sourceChainID,
+		SourceAssetAddr: sourceAssetAddr,
+		DestChainID:     destChainID,
+		DestAssetAddr:   destAssetAddr,
+		Decimals:        decimals,
+		Symbol:          symbol,
+	}
+	if err := api.store.Register(asset); err != nil {
+		return common.Hash{}, err
+	}
+	return asset.AssetID, nil
+}
+
+// ListAssets returns every registered asset pair.
+func (api *PublicAssetAPI) ListAssets() ([]*Asset, error) {
+	return api.store.List()
+}
+
+// DisableAsset stops assetID from being accepted for new cross transactions.
+func (api *PublicAssetAPI) DisableAsset(assetID common.Hash) error {
+	return api.store.Disable(assetID)
+}
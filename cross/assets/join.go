@@ -0,0 +1,20 @@
+package assets
+
+import "github.com/simplechain-org/go-simplechain/common"
+
+// assetIndex is the subset of *db.indexDB's AssetID lookup helpers needed to
+// join a cross transaction's AssetID foreign key back to its Asset.
+type assetIndex interface {
+	AssetIDOf(ctxId common.Hash) (common.Hash, bool)
+}
+
+// Lookup returns the asset a previously-indexed cross transaction was
+// validated against, for callers joining indexDB.Query/One results with
+// asset metadata (symbol, decimals, destination address).
+func (s *AssetStore) Lookup(index assetIndex, ctxId common.Hash) (*Asset, error) {
+	assetID, ok := index.AssetIDOf(ctxId)
+	if !ok {
+		return nil, ErrNotIndexed
+	}
+	return s.GetByID(assetID)
+}
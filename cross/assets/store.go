@@ -0,0 +1,106 @@
+// Package assets maps source-chain tokens onto their destination-chain
+// counterparts, so the synchron keepers and the rest of the cross-chain
+// pipeline can normalize asset representation across chains instead of
+// passing raw, chain-specific addresses and decimals around.
+package assets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rlp"
+
+	"github.com/asdine/storm/v3"
+)
+
+// ErrNotIndexed is returned by Lookup when a cross transaction was never
+// recorded against an asset, e.g. it predates the asset registry.
+var ErrNotIndexed = errors.New("assets: cross transaction has no recorded asset")
+
+// Asset pairs a source-chain token with its destination-chain counterpart.
+type Asset struct {
+	AssetID         common.Hash    `storm:"id"`
+	SourceChainID   uint64         `storm:"index"`
+	SourceAssetAddr common.Address `storm:"index"`
+	DestChainID     uint64
+	DestAssetAddr   common.Address
+	Decimals        uint8
+	Symbol          string
+	Disabled        bool
+}
+
+// AssetID derives the stable id of the (sourceChainID, sourceAssetAddr)
+// pair, used as both the storm primary key and the AssetID foreign key
+// CrossTransactionIndexed rows are joined against.
+func AssetID(sourceChainID uint64, sourceAssetAddr common.Address) common.Hash {
+	enc, _ := rlp.EncodeToBytes([]interface{}{sourceChainID, sourceAssetAddr})
+	return crypto.Keccak256Hash(enc)
+}
+
+// AssetStore persists the registered source/destination asset pairs. It is
+// backed by the same storm root DB as indexDB (or SQL, once the pluggable
+// backend is wired up the same way CtxIndexer is).
+type AssetStore struct {
+	db storm.Node
+}
+
+// NewAssetStore opens the asset registry in its own bucket of rootDB.
+func NewAssetStore(rootDB *storm.DB) *AssetStore {
+	return &AssetStore{db: rootDB.From("assets")}
+}
+
+// Register adds or updates a source/destination asset pair.
+func (s *AssetStore) Register(asset *Asset) error {
+	asset.AssetID = AssetID(asset.SourceChainID, asset.SourceAssetAddr)
+	if err := s.db.Save(asset); err != nil {
+		return fmt.Errorf("assets: register %s failed: %w", asset.AssetID, err)
+	}
+	log.Info("Registered cross-chain asset", "assetID", asset.AssetID, "symbol", asset.Symbol,
+		"sourceChainID", asset.SourceChainID, "destChainID", asset.DestChainID)
+	return nil
+}
+
+// Get looks up the asset pair registered for (sourceChainID, sourceAssetAddr).
+func (s *AssetStore) Get(sourceChainID uint64, sourceAssetAddr common.Address) (*Asset, error) {
+	return s.GetByID(AssetID(sourceChainID, sourceAssetAddr))
+}
+
+// GetByID looks up an asset pair by its AssetID.
+func (s *AssetStore) GetByID(assetID common.Hash) (*Asset, error) {
+	var asset Asset
+	if err := s.db.One("AssetID", assetID, &asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// List returns every registered asset pair, including disabled ones.
+func (s *AssetStore) List() ([]*Asset, error) {
+	var assets []*Asset
+	if err := s.db.All(&assets); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// Disable marks an asset pair as no longer accepted for new cross
+// transactions, without deleting its history.
+func (s *AssetStore) Disable(assetID common.Hash) error {
+	var asset Asset
+	if err := s.db.One("AssetID", assetID, &asset); err != nil {
+		return err
+	}
+	asset.Disabled = true
+	return s.db.Update(&asset)
+}
+
+// Registered reports whether (sourceChainID, sourceAssetAddr) is registered
+// and not disabled, the check the synchron keepers run before accepting a
+// cross transaction for an unrecognized token pair.
+func (s *AssetStore) Registered(sourceChainID uint64, sourceAssetAddr common.Address) bool {
+	asset, err := s.Get(sourceChainID, sourceAssetAddr)
+	return err == nil && !asset.Disabled
+}
@@ -0,0 +1,28 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+func TestAssetIDIsDeterministic(t *testing.T) {
+	chainID := uint64(1)
+	addr := common.HexToAddress("0x0102030405060708091011121314151617181920")
+
+	if AssetID(chainID, addr) != AssetID(chainID, addr) {
+		t.Fatal("AssetID isn't deterministic for the same (sourceChainID, sourceAssetAddr) pair")
+	}
+}
+
+func TestAssetIDDistinguishesChainAndAddr(t *testing.T) {
+	addr := common.HexToAddress("0x0102030405060708091011121314151617181920")
+	other := common.HexToAddress("0x2019181716151413121110090807060504030201")
+
+	if AssetID(1, addr) == AssetID(2, addr) {
+		t.Fatal("AssetID collided across different sourceChainIDs for the same address")
+	}
+	if AssetID(1, addr) == AssetID(1, other) {
+		t.Fatal("AssetID collided across different addresses on the same chain")
+	}
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/simplechain-org/go-simplechain/common"
+
+	"github.com/asdine/storm/v3"
+)
+
+// ctxAsset records the AssetID foreign key a cross transaction was indexed
+// against, kept in its own bucket for the same reason ctxCompletion is: it
+// lets callers join Query/One results with asset metadata (see
+// assets.AssetStore.Lookup) without CrossTransactionIndexed needing to know
+// about the cross/assets package. Query/One themselves return no asset data.
+type ctxAsset struct {
+	CtxId   common.Hash `storm:"id"`
+	AssetID common.Hash `storm:"index"`
+}
+
+func (d *indexDB) assetBucket() storm.Node {
+	return d.root.From("synchron", "asset")
+}
+
+// SetAssetID records which registered asset ctxId was validated against.
+func (d *indexDB) SetAssetID(ctxId, assetID common.Hash) error {
+	if err := d.assetBucket().Save(&ctxAsset{CtxId: ctxId, AssetID: assetID}); err != nil {
+		return ErrCtxDbFailure{fmt.Sprintf("SetAssetID:%s save fail", ctxId.String()), err}
+	}
+	return nil
+}
+
+// AssetIDOf returns the AssetID ctxId was indexed against, if any.
+func (d *indexDB) AssetIDOf(ctxId common.Hash) (common.Hash, bool) {
+	var asset ctxAsset
+	if err := d.assetBucket().One("CtxId", ctxId, &asset); err != nil {
+		return common.Hash{}, false
+	}
+	return asset.AssetID, true
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/asdine/storm/v3"
+)
+
+// Backend selects the storage engine behind a chain's CtxIndexer.
+type Backend string
+
+const (
+	BackendStorm    Backend = "storm"
+	BackendMySQL    Backend = "mysql"
+	BackendPostgres Backend = "postgres"
+)
+
+// Config selects and configures the CtxIndexer backend for a chain.
+// cross.indexdb.backend in the node config maps directly onto Backend.
+type Config struct {
+	Backend   Backend
+	DSN       string // data source name, only used by the mysql/postgres backends
+	CacheSize uint64
+}
+
+// NewCtxIndexer builds the CtxIndexer selected by cfg.Backend. rootDB is only
+// used by the storm backend; it may be nil for mysql/postgres.
+func NewCtxIndexer(chainID *big.Int, cfg Config, rootDB *storm.DB) (CtxIndexer, error) {
+	switch cfg.Backend {
+	case "", BackendStorm:
+		return NewIndexDB(chainID, rootDB, cfg.CacheSize), nil
+	case BackendMySQL, BackendPostgres:
+		return newSQLIndexDB(chainID, cfg.Backend, cfg.DSN)
+	default:
+		return nil, fmt.Errorf("cross: unknown indexdb backend %q", cfg.Backend)
+	}
+}
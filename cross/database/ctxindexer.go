@@ -0,0 +1,53 @@
+package db
+
+import (
+	"math/big"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/event"
+
+	"github.com/asdine/storm/v3/q"
+)
+
+// CtxIndexer is the storage interface for a chain's indexed cross
+// transactions. indexDB is the original BoltDB/storm-backed implementation;
+// sqlIndexDB backs the same interface with MySQL/Postgres instead.
+type CtxIndexer interface {
+	ChainID() *big.Int
+
+	Write(ctx *cc.CrossTransactionWithSignatures) error
+	Read(ctxId common.Hash) (*cc.CrossTransactionWithSignatures, error)
+	Update(id common.Hash, updater func(ctx *CrossTransactionIndexed)) error
+	Has(id common.Hash) bool
+	One(field FieldName, key interface{}) *cc.CrossTransactionWithSignatures
+	Count(filter ...q.Matcher) int
+	Height() uint64
+
+	Range(pageSize int, startCtxID, endCtxID *common.Hash) []*cc.CrossTransactionWithSignatures
+	RangeByNumber(begin, end uint64, pageSize int) []*cc.CrossTransactionWithSignatures
+	Query(pageSize int, startPage int, orderBy []FieldName, reverse bool, filter ...q.Matcher) []*cc.CrossTransactionWithSignatures
+
+	MarkPending(ctxId common.Hash) error
+	MarkIllegal(ctxId common.Hash) error
+	MarkExecuting(ctxId common.Hash) error
+	MarkCompleted(ctxId, execTxHash common.Hash, blockNum uint64) error
+	MarkFinished(ctxId common.Hash) error
+	RangeByStatus(status CtxStatus, pageSize, startPage int) []*cc.CrossTransactionWithSignatures
+	SubscribeStatusChange(ch chan<- StatusEvent) event.Subscription
+	StatusOf(ctxId common.Hash) (CtxStatus, bool)
+	CompletionOf(ctxId common.Hash) (execTxHash common.Hash, blockNum uint64, ok bool)
+
+	SetAssetID(ctxId, assetID common.Hash) error
+	AssetIDOf(ctxId common.Hash) (common.Hash, bool)
+
+	Load() error
+	Repair() error
+	Clean() error
+	Close() error
+}
+
+var (
+	_ CtxIndexer = (*indexDB)(nil)
+	_ CtxIndexer = (*sqlIndexDB)(nil)
+)
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/simplechain-org/go-simplechain/common"
+
+	"github.com/asdine/storm/v3"
+)
+
+// SyncCursor is the last block a synchron keeper has synced from a given
+// remote chain, persisted in its own bucket alongside CrossTransactionIndexed
+// so a restart resumes from where it left off instead of rescanning.
+type SyncCursor struct {
+	ChainID     uint64 `storm:"id"`
+	BlockNumber uint64 `storm:"index"`
+	BlockHash   common.Hash
+}
+
+func (d *indexDB) cursorBucket() storm.Node {
+	return d.root.From("synchron", "cursor")
+}
+
+// LastSyncCursor returns the last synced block of the given remote chain, or
+// nil if nothing has been synced yet.
+func (d *indexDB) LastSyncCursor(chainID uint64) (*SyncCursor, error) {
+	var cursor SyncCursor
+	if err := d.cursorBucket().One("ChainID", chainID, &cursor); err != nil {
+		if err == storm.ErrNotFound {
+			return nil, nil
+		}
+		return nil, ErrCtxDbFailure{fmt.Sprintf("LastSyncCursor:%d load fail", chainID), err}
+	}
+	return &cursor, nil
+}
+
+// UpdateSyncCursor advances the cursor of the given remote chain to number/hash.
+func (d *indexDB) UpdateSyncCursor(chainID, number uint64, hash common.Hash) error {
+	cursor := SyncCursor{ChainID: chainID, BlockNumber: number, BlockHash: hash}
+	if err := d.cursorBucket().Save(&cursor); err != nil {
+		return ErrCtxDbFailure{fmt.Sprintf("UpdateSyncCursor:%d save fail", chainID), err}
+	}
+	return nil
+}
+
+// RollbackSyncCursor rewinds the cursor of the given remote chain to number/hash
+// after a reorg has been detected, so the keeper re-syncs the discarded range.
+func (d *indexDB) RollbackSyncCursor(chainID, number uint64, hash common.Hash) error {
+	return d.UpdateSyncCursor(chainID, number, hash)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/common"
 	"github.com/simplechain-org/go-simplechain/common/math"
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/event"
 	"github.com/simplechain-org/go-simplechain/log"
 
 	"github.com/asdine/storm/v3"
@@ -18,6 +19,10 @@ type indexDB struct {
 	root    *storm.DB // root db of stormDB
 	db      storm.Node
 	cache   *IndexDbCache
+
+	locks       *ctxLocks
+	statusFeed  event.Feed
+	statusScope event.SubscriptionScope
 }
 
 type FieldName = string
@@ -38,8 +43,10 @@ func NewIndexDB(chainID *big.Int, rootDB *storm.DB, cacheSize uint64) *indexDB {
 	log.Info("New IndexDB", "dbName", dbName, "cacheSize", cacheSize)
 	return &indexDB{
 		chainID: chainID,
+		root:    rootDB,
 		db:      rootDB.From(dbName).WithBatch(true),
 		cache:   newIndexDbCache(int(cacheSize)),
+		locks:   newCtxLocks(),
 	}
 }
 
@@ -73,6 +80,7 @@ func (d *indexDB) Clean() error {
 }
 
 func (d *indexDB) Close() error {
+	d.statusScope.Close()
 	return d.db.Commit()
 }
 
@@ -237,4 +245,4 @@ func (d *indexDB) RangeByNumber(begin, end uint64, pageSize int) []*cc.CrossTran
 		results[i] = ctx.ToCrossTransaction()
 	}
 	return results
-}
\ No newline at end of file
+}
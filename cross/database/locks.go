@@ -0,0 +1,56 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+// ctxLocks hands out a per-ctxId mutex so that concurrent callers — most
+// notably the mainchain and sidechain synchron keepers reacting to the same
+// cross transaction from opposite ends — can't race each other's status
+// transitions through a read-modify-write Update call.
+type ctxLocks struct {
+	mu    sync.Mutex
+	locks map[common.Hash]*sync.Mutex
+}
+
+func newCtxLocks() *ctxLocks {
+	return &ctxLocks{locks: make(map[common.Hash]*sync.Mutex)}
+}
+
+func (l *ctxLocks) lock(id common.Hash) func() {
+	m := l.mutexFor(id)
+	m.Lock()
+	return m.Unlock
+}
+
+// lockForTerminal behaves like lock, except its returned unlock also
+// removes id's entry once released. Use it only when the caller knows the
+// ctx is transitioning to a terminal status (Illegal, Finished) and so will
+// never be locked again: without this, a federation-scale bridge indexing
+// millions of cross transactions would keep every one of their mutexes
+// alive for the life of the process.
+func (l *ctxLocks) lockForTerminal(id common.Hash) func() {
+	m := l.mutexFor(id)
+	m.Lock()
+	return func() {
+		m.Unlock()
+		l.mu.Lock()
+		if cur, ok := l.locks[id]; ok && cur == m {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (l *ctxLocks) mutexFor(id common.Hash) *sync.Mutex {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m, ok := l.locks[id]
+	if !ok {
+		m = new(sync.Mutex)
+		l.locks[id] = m
+	}
+	return m
+}
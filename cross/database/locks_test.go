@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+func TestCtxLocksSerializesSameID(t *testing.T) {
+	locks := newCtxLocks()
+	id := common.HexToHash("0x01")
+
+	unlock := locks.lock(id)
+	acquired := make(chan struct{})
+	go func() {
+		locks.lock(id)() // blocks until the first lock is released, then releases itself
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("a second lock on the same ctxId was acquired before the first was released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("the second lock was never acquired after the first was released")
+	}
+}
+
+func TestCtxLocksLockForTerminalEvictsEntry(t *testing.T) {
+	locks := newCtxLocks()
+	id := common.HexToHash("0x01")
+
+	locks.lockForTerminal(id)() // lock and immediately release
+
+	locks.mu.Lock()
+	_, ok := locks.locks[id]
+	locks.mu.Unlock()
+	if ok {
+		t.Fatal("lockForTerminal's unlock didn't evict the mutex for its ctxId")
+	}
+}
+
+func TestCtxLocksDifferentIDsDontBlock(t *testing.T) {
+	locks := newCtxLocks()
+	a := common.HexToHash("0x01")
+	b := common.HexToHash("0x02")
+
+	unlockA := locks.lock(a)
+	defer unlockA()
+
+	acquired := make(chan struct{})
+	go func() {
+		locks.lock(b)()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("locking one ctxId blocked on an unrelated ctxId's lock")
+	}
+}
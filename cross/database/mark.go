@@ -0,0 +1,120 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/event"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+)
+
+// ctxCompletion records the execution details observed for a cross
+// transaction once it reaches CtxStatusCompleted, kept in its own bucket so
+// MarkCompleted doesn't need CrossTransactionIndexed to carry fields that
+// are only meaningful after completion.
+type ctxCompletion struct {
+	CtxId      common.Hash `storm:"id"`
+	ExecTxHash common.Hash
+	BlockNum   uint64
+}
+
+func (d *indexDB) completionBucket() storm.Node {
+	return d.root.From("synchron", "completion")
+}
+
+func (d *indexDB) setStatus(id common.Hash, status CtxStatus) error {
+	var unlock func()
+	if status == CtxStatusIllegal || status == CtxStatusFinished {
+		unlock = d.locks.lockForTerminal(id)
+	} else {
+		unlock = d.locks.lock(id)
+	}
+	defer unlock()
+
+	if err := d.Update(id, func(ctx *CrossTransactionIndexed) {
+		ctx.Status = status
+	}); err != nil {
+		return err
+	}
+
+	d.statusFeed.Send(StatusEvent{CtxId: id, Status: status})
+	return nil
+}
+
+// MarkPending marks ctxId as freshly indexed and awaiting execution on the
+// destination chain.
+func (d *indexDB) MarkPending(ctxId common.Hash) error {
+	return d.setStatus(ctxId, CtxStatusPending)
+}
+
+// MarkIllegal marks ctxId as having failed validation.
+func (d *indexDB) MarkIllegal(ctxId common.Hash) error {
+	return d.setStatus(ctxId, CtxStatusIllegal)
+}
+
+// MarkExecuting marks ctxId as having a matching transaction broadcast on
+// the destination chain, but not yet confirmed.
+func (d *indexDB) MarkExecuting(ctxId common.Hash) error {
+	return d.setStatus(ctxId, CtxStatusExecuting)
+}
+
+// MarkCompleted marks ctxId as executed on the destination chain by
+// execTxHash at blockNum, as observed by a synchron keeper watching for the
+// matching receipt.
+func (d *indexDB) MarkCompleted(ctxId, execTxHash common.Hash, blockNum uint64) error {
+	unlock := d.locks.lock(ctxId)
+	defer unlock()
+
+	if err := d.completionBucket().Save(&ctxCompletion{CtxId: ctxId, ExecTxHash: execTxHash, BlockNum: blockNum}); err != nil {
+		return ErrCtxDbFailure{fmt.Sprintf("MarkCompleted:%s save fail", ctxId.String()), err}
+	}
+	if err := d.Update(ctxId, func(ctx *CrossTransactionIndexed) {
+		ctx.Status = CtxStatusCompleted
+	}); err != nil {
+		return err
+	}
+
+	d.statusFeed.Send(StatusEvent{CtxId: ctxId, Status: CtxStatusCompleted})
+	return nil
+}
+
+// MarkFinished marks ctxId as finished: the finish notice has been relayed
+// back to the source chain and the transaction needs no further action.
+func (d *indexDB) MarkFinished(ctxId common.Hash) error {
+	return d.setStatus(ctxId, CtxStatusFinished)
+}
+
+// StatusOf returns ctxId's current status, for callers (e.g. MigrateToSQL)
+// that need it without pulling a full CrossTransactionWithSignatures.
+func (d *indexDB) StatusOf(ctxId common.Hash) (CtxStatus, bool) {
+	ctx, err := d.get(ctxId)
+	if err != nil {
+		return 0, false
+	}
+	return ctx.Status, true
+}
+
+// CompletionOf returns the execution details MarkCompleted recorded for
+// ctxId, if it has reached CtxStatusCompleted (or later).
+func (d *indexDB) CompletionOf(ctxId common.Hash) (execTxHash common.Hash, blockNum uint64, ok bool) {
+	var completion ctxCompletion
+	if err := d.completionBucket().One("CtxId", ctxId, &completion); err != nil {
+		return common.Hash{}, 0, false
+	}
+	return completion.ExecTxHash, completion.BlockNum, true
+}
+
+// RangeByStatus returns cross transactions in the given status, most recent
+// page first when startPage and pageSize are both positive.
+func (d *indexDB) RangeByStatus(status CtxStatus, pageSize, startPage int) []*cc.CrossTransactionWithSignatures {
+	return d.Query(pageSize, startPage, []FieldName{BlockNumField}, true, q.Eq(StatusField, status))
+}
+
+// SubscribeStatusChange notifies ch whenever a cross transaction's status
+// changes, so RPC clients and synchron keepers can react without polling.
+func (d *indexDB) SubscribeStatusChange(ch chan<- StatusEvent) event.Subscription {
+	return d.statusScope.Track(d.statusFeed.Subscribe(ch))
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/log"
+)
+
+// MigrateToSQL copies every cross transaction from src into dst, paging
+// through src.Range in PK order so dst ends up with the same PK ordering
+// and Range(startCtxID, endCtxID) keeps returning identical pages on either
+// backend. Intended for moving a chain from the storm backend onto the
+// pluggable SQL backend described by Config.Backend.
+//
+// Write only rebuilds a row from its CrossTransactionWithSignatures, which
+// carries no Status, completion, or asset history, so each row's Status/
+// ctxCompletion/ctxAsset record is copied across separately afterward via
+// migrateHistory; otherwise every migrated row would silently reset to
+// CtxStatusPending and lose that history.
+func MigrateToSQL(src, dst CtxIndexer, pageSize int) (int, error) {
+	var (
+		cursor   *common.Hash
+		migrated int
+	)
+	for {
+		batch := src.Range(pageSize, cursor, nil)
+		if len(batch) == 0 {
+			break
+		}
+		for _, ctx := range batch {
+			if err := dst.Write(ctx); err != nil {
+				return migrated, fmt.Errorf("cross: migrate ctx %s: %w", ctx.ID(), err)
+			}
+			if err := migrateHistory(src, dst, ctx.ID()); err != nil {
+				return migrated, fmt.Errorf("cross: migrate ctx %s history: %w", ctx.ID(), err)
+			}
+			migrated++
+		}
+		last := batch[len(batch)-1].ID()
+		cursor = &last
+
+		log.Info("Migrating cross transactions to SQL", "chainID", src.ChainID(), "migrated", migrated)
+		if len(batch) < pageSize {
+			break
+		}
+	}
+	return migrated, nil
+}
+
+// migrateHistory carries ctxId's AssetID tag and Status (including its
+// ctxCompletion record, if any) from src to dst, replaying the same Mark*
+// calls a synchron keeper would have made so dst's history matches src's.
+// A Completed or Finished row with no ctxCompletion record in src cannot be
+// replayed through MarkCompleted, so it is carried across as Executing.
+func migrateHistory(src, dst CtxIndexer, ctxId common.Hash) error {
+	if assetID, ok := src.AssetIDOf(ctxId); ok {
+		if err := dst.SetAssetID(ctxId, assetID); err != nil {
+			return err
+		}
+	}
+
+	status, ok := src.StatusOf(ctxId)
+	if !ok {
+		return nil
+	}
+	switch status {
+	case CtxStatusPending:
+		return nil // Write already leaves dst at CtxStatusPending
+	case CtxStatusIllegal:
+		return dst.MarkIllegal(ctxId)
+	case CtxStatusExecuting:
+		return dst.MarkExecuting(ctxId)
+	case CtxStatusCompleted, CtxStatusFinished:
+		execTxHash, blockNum, ok := src.CompletionOf(ctxId)
+		if !ok {
+			return dst.MarkExecuting(ctxId)
+		}
+		if err := dst.MarkCompleted(ctxId, execTxHash, blockNum); err != nil {
+			return err
+		}
+		if status == CtxStatusFinished {
+			return dst.MarkFinished(ctxId)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/event"
+
+	"github.com/asdine/storm/v3/q"
+)
+
+// fakeCtxIndexer is a CtxIndexer stub recording the Mark*/SetAssetID calls
+// migrateHistory makes, so tests can assert on them without a real storm/SQL
+// backend. Only the methods migrateHistory actually touches carry behavior;
+// everything else is an unused no-op.
+type fakeCtxIndexer struct {
+	status        CtxStatus
+	hasStatus     bool
+	assetID       common.Hash
+	hasAssetID    bool
+	execTxHash    common.Hash
+	blockNum      uint64
+	hasCompletion bool
+
+	marked   []CtxStatus
+	assetSet common.Hash
+}
+
+func (f *fakeCtxIndexer) ChainID() *big.Int                                  { return big.NewInt(1) }
+func (f *fakeCtxIndexer) Write(ctx *cc.CrossTransactionWithSignatures) error { return nil }
+func (f *fakeCtxIndexer) Read(ctxId common.Hash) (*cc.CrossTransactionWithSignatures, error) {
+	return nil, nil
+}
+func (f *fakeCtxIndexer) Update(id common.Hash, updater func(ctx *CrossTransactionIndexed)) error {
+	return nil
+}
+func (f *fakeCtxIndexer) Has(id common.Hash) bool { return true }
+func (f *fakeCtxIndexer) One(field FieldName, key interface{}) *cc.CrossTransactionWithSignatures {
+	return nil
+}
+func (f *fakeCtxIndexer) Count(filter ...q.Matcher) int { return 0 }
+func (f *fakeCtxIndexer) Height() uint64                { return 0 }
+func (f *fakeCtxIndexer) Range(pageSize int, startCtxID, endCtxID *common.Hash) []*cc.CrossTransactionWithSignatures {
+	return nil
+}
+func (f *fakeCtxIndexer) RangeByNumber(begin, end uint64, pageSize int) []*cc.CrossTransactionWithSignatures {
+	return nil
+}
+func (f *fakeCtxIndexer) Query(pageSize int, startPage int, orderBy []FieldName, reverse bool, filter ...q.Matcher) []*cc.CrossTransactionWithSignatures {
+	return nil
+}
+func (f *fakeCtxIndexer) MarkPending(ctxId common.Hash) error {
+	f.marked = append(f.marked, CtxStatusPending)
+	return nil
+}
+func (f *fakeCtxIndexer) MarkIllegal(ctxId common.Hash) error {
+	f.marked = append(f.marked, CtxStatusIllegal)
+	return nil
+}
+func (f *fakeCtxIndexer) MarkExecuting(ctxId common.Hash) error {
+	f.marked = append(f.marked, CtxStatusExecuting)
+	return nil
+}
+func (f *fakeCtxIndexer) MarkCompleted(ctxId, execTxHash common.Hash, blockNum uint64) error {
+	f.marked = append(f.marked, CtxStatusCompleted)
+	return nil
+}
+func (f *fakeCtxIndexer) MarkFinished(ctxId common.Hash) error {
+	f.marked = append(f.marked, CtxStatusFinished)
+	return nil
+}
+func (f *fakeCtxIndexer) RangeByStatus(status CtxStatus, pageSize, startPage int) []*cc.CrossTransactionWithSignatures {
+	return nil
+}
+func (f *fakeCtxIndexer) SubscribeStatusChange(ch chan<- StatusEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+func (f *fakeCtxIndexer) SetAssetID(ctxId, assetID common.Hash) error {
+	f.assetSet = assetID
+	return nil
+}
+func (f *fakeCtxIndexer) AssetIDOf(ctxId common.Hash) (common.Hash, bool) {
+	return f.assetID, f.hasAssetID
+}
+func (f *fakeCtxIndexer) StatusOf(ctxId common.Hash) (CtxStatus, bool) { return f.status, f.hasStatus }
+func (f *fakeCtxIndexer) CompletionOf(ctxId common.Hash) (common.Hash, uint64, bool) {
+	return f.execTxHash, f.blockNum, f.hasCompletion
+}
+func (f *fakeCtxIndexer) Load() error   { return nil }
+func (f *fakeCtxIndexer) Repair() error { return nil }
+func (f *fakeCtxIndexer) Clean() error  { return nil }
+func (f *fakeCtxIndexer) Close() error  { return nil }
+
+var _ CtxIndexer = (*fakeCtxIndexer)(nil)
+
+func TestMigrateHistoryCarriesAssetAndStatus(t *testing.T) {
+	ctxId := common.HexToHash("0x01")
+	assetID := common.HexToHash("0x02")
+
+	src := &fakeCtxIndexer{
+		status: CtxStatusExecuting, hasStatus: true,
+		assetID: assetID, hasAssetID: true,
+	}
+	dst := &fakeCtxIndexer{}
+
+	if err := migrateHistory(src, dst, ctxId); err != nil {
+		t.Fatalf("migrateHistory: %v", err)
+	}
+	if dst.assetSet != assetID {
+		t.Fatalf("dst AssetID = %s, want %s", dst.assetSet, assetID)
+	}
+	if len(dst.marked) != 1 || dst.marked[0] != CtxStatusExecuting {
+		t.Fatalf("dst marked = %v, want [Executing]", dst.marked)
+	}
+}
+
+func TestMigrateHistoryCarriesCompletion(t *testing.T) {
+	ctxId := common.HexToHash("0x01")
+	execTxHash := common.HexToHash("0x03")
+
+	src := &fakeCtxIndexer{
+		status: CtxStatusCompleted, hasStatus: true,
+		execTxHash: execTxHash, blockNum: 42, hasCompletion: true,
+	}
+	dst := &fakeCtxIndexer{}
+
+	if err := migrateHistory(src, dst, ctxId); err != nil {
+		t.Fatalf("migrateHistory: %v", err)
+	}
+	if len(dst.marked) != 1 || dst.marked[0] != CtxStatusCompleted {
+		t.Fatalf("dst marked = %v, want [Completed]", dst.marked)
+	}
+}
+
+func TestMigrateHistoryPendingIsNoop(t *testing.T) {
+	src := &fakeCtxIndexer{status: CtxStatusPending, hasStatus: true}
+	dst := &fakeCtxIndexer{}
+
+	if err := migrateHistory(src, dst, common.HexToHash("0x01")); err != nil {
+		t.Fatalf("migrateHistory: %v", err)
+	}
+	if len(dst.marked) != 0 {
+		t.Fatalf("dst marked = %v, want none for a still-pending ctx", dst.marked)
+	}
+}
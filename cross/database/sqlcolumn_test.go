@@ -0,0 +1,39 @@
+package db
+
+import "testing"
+
+func TestSQLColumnWhitelist(t *testing.T) {
+	cases := []struct {
+		field FieldName
+		want  string
+	}{
+		{PK, "pk"},
+		{CtxIdIndex, "ctx_id"},
+		{TxHashIndex, "tx_hash"},
+		{PriceIndex, "price"},
+		{StatusField, "status"},
+		{FromField, "from"},
+		{DestinationValue, "destination_value"},
+		{BlockNumField, "block_num"},
+	}
+	for _, c := range cases {
+		got, ok := sqlColumn(c.field)
+		if !ok {
+			t.Errorf("sqlColumn(%q) reported ok=false, want the whitelisted column %q", c.field, c.want)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("sqlColumn(%q) = %q, want %q", c.field, got, c.want)
+		}
+	}
+}
+
+func TestSQLColumnRejectsUnknownField(t *testing.T) {
+	// the motivating case: an arbitrary orderBy string forwarded from an
+	// HTTP request must never reach a query unvalidated.
+	for _, field := range []FieldName{"", "1 OR 1=1", "status; DROP TABLE cross_transaction_indexeds"} {
+		if _, ok := sqlColumn(field); ok {
+			t.Errorf("sqlColumn(%q) reported ok=true, want the unknown field rejected", field)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package db
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/event"
+	"github.com/simplechain-org/go-simplechain/log"
+
+	"github.com/asdine/storm/v3/q"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sqlIndexDB is a CtxIndexer backed by MySQL/Postgres via gorm, trading
+// storm's cache/Select API for a normal SQL connection pool.
+type sqlIndexDB struct {
+	chainID *big.Int
+	gdb     *gorm.DB
+
+	statusFeed  event.Feed
+	statusScope event.SubscriptionScope
+	locks       *ctxLocks
+}
+
+func newSQLIndexDB(chainID *big.Int, backend Backend, dsn string) (*sqlIndexDB, error) {
+	var dialector gorm.Dialector
+	switch backend {
+	case BackendMySQL:
+		dialector = mysql.Open(dsn)
+	case BackendPostgres:
+		dialector = postgres.Open(dsn)
+	default:
+		return nil, fmt.Errorf("cross: sql indexdb does not support backend %q", backend)
+	}
+
+	gdb, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("cross: open %s indexdb: %w", backend, err)
+	}
+	if err := gdb.AutoMigrate(&CrossTransactionIndexed{}, &ctxCompletion{}, &ctxAsset{}); err != nil {
+		return nil, fmt.Errorf("cross: migrate %s indexdb: %w", backend, err)
+	}
+	for _, idx := range []string{"CtxId", "TxHash", "BlockNum", "Status", "From", "DestinationValue"} {
+		if !gdb.Migrator().HasIndex(&CrossTransactionIndexed{}, idx) {
+			if err := gdb.Migrator().CreateIndex(&CrossTransactionIndexed{}, idx); err != nil {
+				return nil, fmt.Errorf("cross: create index %s on %s indexdb: %w", idx, backend, err)
+			}
+		}
+	}
+
+	log.Info("New SQL IndexDB", "backend", backend, "chainID", chainID)
+	return &sqlIndexDB{chainID: chainID, gdb: gdb, locks: newCtxLocks()}, nil
+}
+
+func (d *sqlIndexDB) ChainID() *big.Int { return d.chainID }
+
+func (d *sqlIndexDB) Write(ctx *cc.CrossTransactionWithSignatures) error {
+	var old CrossTransactionIndexed
+	err := d.gdb.Where("ctx_id = ?", ctx.ID()).First(&old).Error
+	switch {
+	case err == nil:
+		if old.BlockHash != ctx.BlockHash() {
+			return ErrCtxDbFailure{err: fmt.Errorf("blockchain reorg, txID:%s, old:%s, new:%s",
+				ctx.ID(), old.BlockHash.String(), ctx.BlockHash().String())}
+		}
+		return nil
+	case err != gorm.ErrRecordNotFound:
+		return ErrCtxDbFailure{fmt.Sprintf("Write:%s lookup fail", ctx.ID().String()), err}
+	}
+
+	persist := NewCrossTransactionIndexed(ctx)
+	if err := d.gdb.Create(persist).Error; err != nil {
+		return ErrCtxDbFailure{fmt.Sprintf("Write:%s save fail", ctx.ID().String()), err}
+	}
+	return nil
+}
+
+func (d *sqlIndexDB) Read(ctxId common.Hash) (*cc.CrossTransactionWithSignatures, error) {
+	ctx, err := d.get(ctxId)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.ToCrossTransaction(), nil
+}
+
+func (d *sqlIndexDB) get(ctxId common.Hash) (*CrossTransactionIndexed, error) {
+	var ctx CrossTransactionIndexed
+	if err := d.gdb.Where("ctx_id = ?", ctxId).First(&ctx).Error; err != nil {
+		return nil, ErrCtxDbFailure{fmt.Sprintf("get ctx:%s failed", ctxId.String()), err}
+	}
+	return &ctx, nil
+}
+
+func (d *sqlIndexDB) One(field FieldName, key interface{}) *cc.CrossTransactionWithSignatures {
+	column, ok := sqlColumn(field)
+	if !ok {
+		return nil
+	}
+	var ctx CrossTransactionIndexed
+	if err := d.gdb.Where(map[string]interface{}{column: key}).First(&ctx).Error; err != nil {
+		return nil
+	}
+	return ctx.ToCrossTransaction()
+}
+
+// Update applies updater to ctxId under its per-ctxId lock. Callers already
+// holding that lock (MarkCompleted) must use the unexported update instead,
+// since ctxLocks' mutex isn't reentrant.
+func (d *sqlIndexDB) Update(id common.Hash, updater func(ctx *CrossTransactionIndexed)) error {
+	unlock := d.locks.lock(id)
+	defer unlock()
+	return d.update(id, updater)
+}
+
+func (d *sqlIndexDB) update(id common.Hash, updater func(ctx *CrossTransactionIndexed)) error {
+	ctx, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	updater(ctx) // updater should never be allowed to modify PK or ctxID!
+	if err := d.gdb.Save(ctx).Error; err != nil {
+		return ErrCtxDbFailure{"Update save fail", err}
+	}
+	return nil
+}
+
+func (d *sqlIndexDB) Has(id common.Hash) bool {
+	_, err := d.get(id)
+	return err == nil
+}
+
+func (d *sqlIndexDB) Count(filter ...q.Matcher) int {
+	if len(filter) == 0 {
+		var count int64
+		d.gdb.Model(&CrossTransactionIndexed{}).Count(&count)
+		return int(count)
+	}
+
+	// storm matchers only know how to evaluate themselves against a decoded
+	// Go value, not compile to a SQL WHERE clause, so an arbitrary filter
+	// still costs a full table scan. RangeByStatus below avoids this path
+	// entirely for the one filter shape the rest of this package uses.
+	var ctxs []*CrossTransactionIndexed
+	d.gdb.Find(&ctxs)
+	return len(applyMatchers(ctxs, filter))
+}
+
+func (d *sqlIndexDB) Height() uint64 {
+	var ctx CrossTransactionIndexed
+	if err := d.gdb.Order("block_num desc").First(&ctx).Error; err != nil {
+		return 0
+	}
+	return ctx.BlockNum
+}
+
+// Range mirrors indexDB.Range's PK semantics: startCtxID/endCtxID bound the
+// page by the PK of the row they identify, not by CtxId value, so callers
+// paging across the storm and SQL backends see identical ordering.
+func (d *sqlIndexDB) Range(pageSize int, startCtxID, endCtxID *common.Hash) []*cc.CrossTransactionWithSignatures {
+	query := d.gdb.Order("pk asc")
+	if startCtxID != nil {
+		start, err := d.get(*startCtxID)
+		if err != nil {
+			return nil
+		}
+		query = query.Where("pk > ?", start.PK)
+	}
+	if endCtxID != nil {
+		end, err := d.get(*endCtxID)
+		if err != nil {
+			return nil
+		}
+		query = query.Where("pk <= ?", end.PK)
+	}
+	if pageSize > 0 {
+		query = query.Limit(pageSize)
+	}
+
+	var list []*CrossTransactionIndexed
+	if err := query.Find(&list).Error; err != nil {
+		log.Debug("range return no result", "startID", startCtxID, "endID", endCtxID, "err", err)
+		return nil
+	}
+	return toCrossTransactions(list)
+}
+
+func (d *sqlIndexDB) RangeByNumber(begin, end uint64, pageSize int) []*cc.CrossTransactionWithSignatures {
+	var list []*CrossTransactionIndexed
+	if err := d.gdb.Where("block_num >= ? AND block_num <= ?", begin, end).
+		Order("block_num asc").Limit(pageSize).Find(&list).Error; err != nil || len(list) == 0 {
+		return nil
+	}
+
+	// pull in every ctx sharing the last row's block number, matching the
+	// storm backend's "whole block" page boundary.
+	last := list[len(list)-1].BlockNum
+	var lasts []*CrossTransactionIndexed
+	d.gdb.Where("block_num = ?", last).Find(&lasts)
+	for i, tx := range list {
+		if tx.BlockNum == last {
+			list = list[:i]
+			break
+		}
+	}
+	list = append(list, lasts...)
+	return toCrossTransactions(list)
+}
+
+// Query runs orderBy/reverse in SQL always, and pushes pagination down too
+// when filter is empty. A non-empty filter still needs evaluating in Go
+// since storm matchers only know how to match themselves against a decoded
+// Go value, not compile to a SQL WHERE clause, so that case pages in Go
+// after loading the (unfiltered) result set.
+func (d *sqlIndexDB) Query(pageSize int, startPage int, orderBy []FieldName, reverse bool, filter ...q.Matcher) []*cc.CrossTransactionWithSignatures {
+	if pageSize > 0 && startPage <= 0 {
+		return nil
+	}
+
+	query := d.gdb
+	for _, field := range orderBy {
+		column, ok := sqlColumn(field)
+		if !ok {
+			continue
+		}
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: reverse})
+	}
+	if len(orderBy) == 0 && reverse {
+		// like storm's Reverse without OrderBy: reverse PK order.
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "pk"}, Desc: true})
+	}
+
+	if len(filter) == 0 {
+		if pageSize > 0 {
+			query = query.Limit(pageSize).Offset(pageSize * (startPage - 1))
+		}
+		var ctxs []*CrossTransactionIndexed
+		query.Find(&ctxs)
+		return toCrossTransactions(ctxs)
+	}
+
+	var ctxs []*CrossTransactionIndexed
+	query.Find(&ctxs)
+	ctxs = applyMatchers(ctxs, filter)
+
+	if pageSize > 0 {
+		skip := pageSize * (startPage - 1)
+		if skip >= len(ctxs) {
+			return nil
+		}
+		end := skip + pageSize
+		if end > len(ctxs) {
+			end = len(ctxs)
+		}
+		ctxs = ctxs[skip:end]
+	}
+	return toCrossTransactions(ctxs)
+}
+
+func (d *sqlIndexDB) setStatus(id common.Hash, status CtxStatus) error {
+	// Illegal/Finished are terminal: evict the per-id lock once set so it
+	// doesn't leak for the rest of the process's life. See ctxLocks.lockForTerminal.
+	var unlock func()
+	if status == CtxStatusIllegal || status == CtxStatusFinished {
+		unlock = d.locks.lockForTerminal(id)
+	} else {
+		unlock = d.locks.lock(id)
+	}
+	if err := d.update(id, func(ctx *CrossTransactionIndexed) { ctx.Status = status }); err != nil {
+		unlock()
+		return err
+	}
+	unlock()
+	d.statusFeed.Send(StatusEvent{CtxId: id, Status: status})
+	return nil
+}
+
+func (d *sqlIndexDB) MarkPending(ctxId common.Hash) error {
+	return d.setStatus(ctxId, CtxStatusPending)
+}
+func (d *sqlIndexDB) MarkIllegal(ctxId common.Hash) error {
+	return d.setStatus(ctxId, CtxStatusIllegal)
+}
+func (d *sqlIndexDB) MarkExecuting(ctxId common.Hash) error {
+	return d.setStatus(ctxId, CtxStatusExecuting)
+}
+func (d *sqlIndexDB) MarkFinished(ctxId common.Hash) error {
+	return d.setStatus(ctxId, CtxStatusFinished)
+}
+
+func (d *sqlIndexDB) MarkCompleted(ctxId, execTxHash common.Hash, blockNum uint64) error {
+	unlock := d.locks.lock(ctxId)
+	defer unlock()
+
+	completion := ctxCompletion{CtxId: ctxId, ExecTxHash: execTxHash, BlockNum: blockNum}
+	if err := d.gdb.Save(&completion).Error; err != nil {
+		return ErrCtxDbFailure{fmt.Sprintf("MarkCompleted:%s save fail", ctxId.String()), err}
+	}
+	// use the lock-free update, not Update: the lock above is already held
+	// and ctxLocks' mutex isn't reentrant.
+	if err := d.update(ctxId, func(ctx *CrossTransactionIndexed) { ctx.Status = CtxStatusCompleted }); err != nil {
+		return err
+	}
+	d.statusFeed.Send(StatusEvent{CtxId: ctxId, Status: CtxStatusCompleted})
+	return nil
+}
+
+// StatusOf returns ctxId's current status, for callers (e.g. MigrateToSQL)
+// that need it without pulling a full CrossTransactionWithSignatures.
+func (d *sqlIndexDB) StatusOf(ctxId common.Hash) (CtxStatus, bool) {
+	ctx, err := d.get(ctxId)
+	if err != nil {
+		return 0, false
+	}
+	return ctx.Status, true
+}
+
+// CompletionOf returns the execution details MarkCompleted recorded for
+// ctxId, if it has reached CtxStatusCompleted (or later).
+func (d *sqlIndexDB) CompletionOf(ctxId common.Hash) (execTxHash common.Hash, blockNum uint64, ok bool) {
+	var completion ctxCompletion
+	if err := d.gdb.Where(map[string]interface{}{"ctx_id": ctxId}).First(&completion).Error; err != nil {
+		return common.Hash{}, 0, false
+	}
+	return completion.ExecTxHash, completion.BlockNum, true
+}
+
+// RangeByStatus queries status directly in SQL rather than going through
+// Query with a q.Eq filter, so the hot status-lookup path (also what
+// httpapi's /v1/ctx?status= serves) gets a real WHERE clause instead of a
+// full table scan filtered in Go.
+func (d *sqlIndexDB) RangeByStatus(status CtxStatus, pageSize, startPage int) []*cc.CrossTransactionWithSignatures {
+	if pageSize > 0 && startPage <= 0 {
+		return nil
+	}
+	query := d.gdb.Where(map[string]interface{}{"status": status}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "block_num"}, Desc: true})
+	if pageSize > 0 {
+		query = query.Limit(pageSize).Offset(pageSize * (startPage - 1))
+	}
+	var ctxs []*CrossTransactionIndexed
+	query.Find(&ctxs)
+	return toCrossTransactions(ctxs)
+}
+
+func (d *sqlIndexDB) SubscribeStatusChange(ch chan<- StatusEvent) event.Subscription {
+	return d.statusScope.Track(d.statusFeed.Subscribe(ch))
+}
+
+// SetAssetID records which registered asset ctxId was validated against.
+func (d *sqlIndexDB) SetAssetID(ctxId, assetID common.Hash) error {
+	if err := d.gdb.Save(&ctxAsset{CtxId: ctxId, AssetID: assetID}).Error; err != nil {
+		return ErrCtxDbFailure{fmt.Sprintf("SetAssetID:%s save fail", ctxId.String()), err}
+	}
+	return nil
+}
+
+// AssetIDOf returns the AssetID ctxId was indexed against, if any.
+func (d *sqlIndexDB) AssetIDOf(ctxId common.Hash) (common.Hash, bool) {
+	var asset ctxAsset
+	if err := d.gdb.Where(map[string]interface{}{"ctx_id": ctxId}).First(&asset).Error; err != nil {
+		return common.Hash{}, false
+	}
+	return asset.AssetID, true
+}
+
+func (d *sqlIndexDB) Load() error { return nil }
+
+func (d *sqlIndexDB) Repair() error { return nil }
+
+func (d *sqlIndexDB) Clean() error {
+	return d.gdb.Where("1 = 1").Delete(&CrossTransactionIndexed{}).Error
+}
+
+func (d *sqlIndexDB) Close() error {
+	d.statusScope.Close()
+	sqlDB, err := d.gdb.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// sqlColumn maps a storm FieldName to its SQL column, reporting ok=false for
+// anything outside this known set. A caller-supplied FieldName (e.g. the
+// orderBy query param httpapi forwards from an HTTP request) must never
+// reach a query unvalidated, or it becomes a SQL injection into ORDER BY.
+func sqlColumn(field FieldName) (name string, ok bool) {
+	switch field {
+	case PK:
+		return "pk", true
+	case CtxIdIndex:
+		return "ctx_id", true
+	case TxHashIndex:
+		return "tx_hash", true
+	case PriceIndex:
+		return "price", true
+	case StatusField:
+		return "status", true
+	case FromField:
+		return "from", true
+	case DestinationValue:
+		return "destination_value", true
+	case BlockNumField:
+		return "block_num", true
+	default:
+		return "", false
+	}
+}
+
+func applyMatchers(ctxs []*CrossTransactionIndexed, filter []q.Matcher) []*CrossTransactionIndexed {
+	if len(filter) == 0 {
+		return ctxs
+	}
+	var matched []*CrossTransactionIndexed
+	for _, ctx := range ctxs {
+		ok := true
+		for _, m := range filter {
+			if pass, err := m.Match(ctx); err != nil || !pass {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, ctx)
+		}
+	}
+	return matched
+}
+
+func toCrossTransactions(ctxs []*CrossTransactionIndexed) []*cc.CrossTransactionWithSignatures {
+	results := make([]*cc.CrossTransactionWithSignatures, len(ctxs))
+	for i, ctx := range ctxs {
+		results[i] = ctx.ToCrossTransaction()
+	}
+	return results
+}
@@ -0,0 +1,42 @@
+package db
+
+import "github.com/simplechain-org/go-simplechain/common"
+
+// CtxStatus is the lifecycle state of a cross-transaction as observed by the
+// local chain: it starts Pending once indexed, moves to Executing once a
+// matching receipt is seen on the destination chain, Completed once that
+// receipt is confirmed, and Finished once the finish notice has been
+// relayed back. Illegal marks a transaction that failed validation.
+type CtxStatus uint8
+
+const (
+	CtxStatusPending CtxStatus = iota
+	CtxStatusIllegal
+	CtxStatusExecuting
+	CtxStatusCompleted
+	CtxStatusFinished
+)
+
+func (s CtxStatus) String() string {
+	switch s {
+	case CtxStatusPending:
+		return "pending"
+	case CtxStatusIllegal:
+		return "illegal"
+	case CtxStatusExecuting:
+		return "executing"
+	case CtxStatusCompleted:
+		return "completed"
+	case CtxStatusFinished:
+		return "finished"
+	default:
+		return "unknown"
+	}
+}
+
+// StatusEvent is emitted on SubscribeStatusChange whenever a cross
+// transaction's status changes.
+type StatusEvent struct {
+	CtxId  common.Hash
+	Status CtxStatus
+}
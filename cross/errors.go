@@ -0,0 +1,17 @@
+package cross
+
+import "fmt"
+
+// ErrUnknownAsset is returned by the synchron keepers' handleDeposit/
+// handleWithdraw when a cross transaction's source/destination token pair
+// has not been registered in the asset registry (or is disabled), so
+// unrecognized tokens never reach ctxStore/rtxStore through a keeper.
+// ValidateCtx itself does not check the asset registry.
+type ErrUnknownAsset struct {
+	SourceChainID uint64
+	SourceAsset   string
+}
+
+func (e ErrUnknownAsset) Error() string {
+	return fmt.Sprintf("cross: asset %s on chain %d is not registered", e.SourceAsset, e.SourceChainID)
+}
@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/cross/database"
+)
+
+// ctxResponse is the body of a successful GET /v1/ctx.
+type ctxResponse struct {
+	Height int64                                `json:"height"`
+	Cursor string                               `json:"cursor"` // ctxId of the last row in Data, "" if Data is empty
+	Data   []*cc.CrossTransactionWithSignatures `json:"data"`
+}
+
+// reorgResponse is the body of an error response. On a 409 (the client's
+// from cursor has been invalidated by a reorg) there's no general way to
+// compute the nearest still-valid ctx PK to resume from, so Cursor is left
+// empty: the client must restart from the beginning via page/pageSize
+// instead of from.
+type reorgResponse struct {
+	Error  string `json:"error"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// orderableFields whitelists the orderBy query param against the FieldName
+// values Query actually knows how to sort by, so an arbitrary string can't
+// reach the backend's ORDER BY clause unvalidated.
+var orderableFields = map[string]db.FieldName{
+	db.PK:               db.PK,
+	db.CtxIdIndex:       db.CtxIdIndex,
+	db.TxHashIndex:      db.TxHashIndex,
+	db.PriceIndex:       db.PriceIndex,
+	db.StatusField:      db.StatusField,
+	db.FromField:        db.FromField,
+	db.DestinationValue: db.DestinationValue,
+	db.BlockNumField:    db.BlockNumField,
+}
+
+// handleQuery serves GET /v1/ctx?status=&from=&page=&pageSize=&orderBy=&reverse=
+//
+// from, if set, takes priority: it pages by PK through Range, the one
+// reorg-safe cursor both backends share, and status/orderBy/reverse are
+// ignored. Without from, status pages through RangeByStatus and anything
+// else pages through Query with page/pageSize offsets, which isn't
+// reorg-safe and shouldn't be used for a client that needs to resume a feed.
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	defer requestTimer("ctx").UpdateSince(requestStart())
+
+	query := r.URL.Query()
+
+	pageSize, err := intParam(query, "pageSize", 100)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := intParam(query, "page", 1)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reverse, _ := strconv.ParseBool(query.Get("reverse"))
+
+	var data []*cc.CrossTransactionWithSignatures
+	switch {
+	case query.Get("from") != "":
+		fromHash := common.HexToHash(query.Get("from"))
+		if !s.indexer.Has(fromHash) {
+			// the row the client last saw has been reorged out from under
+			// it. We have no reorg-safe way to find the nearest ctx PK it
+			// could resume from, so don't hand back a height dressed up as
+			// a cursor (the client would send it right back as a from=
+			// hash next request and loop on this 409 forever) — report the
+			// conflict and let the client restart paging from the
+			// beginning instead.
+			requestCounter("ctx", "reorg").Inc(1)
+			writeJSON(w, http.StatusConflict, reorgResponse{Error: "cursor invalidated by reorg"})
+			return
+		}
+		data = s.indexer.Range(pageSize, &fromHash, nil)
+
+	case query.Get("status") != "":
+		n, err := strconv.ParseUint(query.Get("status"), 10, 8)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "invalid status")
+			return
+		}
+		data = s.indexer.RangeByStatus(db.CtxStatus(n), pageSize, page)
+
+	default:
+		orderBy := []db.FieldName{db.BlockNumField}
+		if by := query.Get("orderBy"); by != "" {
+			field, ok := orderableFields[by]
+			if !ok {
+				httpError(w, http.StatusBadRequest, "invalid orderBy")
+				return
+			}
+			orderBy = []db.FieldName{field}
+		}
+		data = s.indexer.Query(pageSize, page, orderBy, reverse)
+	}
+
+	resp := ctxResponse{Height: int64(s.indexer.Height()), Data: data}
+	if len(data) > 0 {
+		resp.Cursor = data[len(data)-1].ID().String()
+	}
+
+	requestCounter("ctx", "ok").Inc(1)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func intParam(query map[string][]string, name string, def int) (int, error) {
+	v := query[name]
+	if len(v) == 0 || v[0] == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v[0])
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, reorgResponse{Error: msg})
+}
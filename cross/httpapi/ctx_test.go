@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/cross/database"
+	"github.com/simplechain-org/go-simplechain/event"
+
+	"github.com/asdine/storm/v3/q"
+)
+
+// fakeIndexer is a db.CtxIndexer whose Query/RangeByStatus/Range/Has/Height
+// are stubbed per test; every other method is a no-op, since handleQuery
+// never reaches them.
+type fakeIndexer struct {
+	height        uint64
+	has           bool
+	rangeResult   []*cc.CrossTransactionWithSignatures
+	rangeByStatus []*cc.CrossTransactionWithSignatures
+	queryResult   []*cc.CrossTransactionWithSignatures
+	lastOrderBy   []db.FieldName
+	lastFromCtxID *common.Hash
+}
+
+func (f *fakeIndexer) ChainID() *big.Int                                  { return big.NewInt(1) }
+func (f *fakeIndexer) Write(ctx *cc.CrossTransactionWithSignatures) error { return nil }
+func (f *fakeIndexer) Read(ctxId common.Hash) (*cc.CrossTransactionWithSignatures, error) {
+	return nil, nil
+}
+func (f *fakeIndexer) Update(id common.Hash, updater func(ctx *db.CrossTransactionIndexed)) error {
+	return nil
+}
+func (f *fakeIndexer) Has(id common.Hash) bool { return f.has }
+func (f *fakeIndexer) One(field db.FieldName, key interface{}) *cc.CrossTransactionWithSignatures {
+	return nil
+}
+func (f *fakeIndexer) Count(filter ...q.Matcher) int { return 0 }
+func (f *fakeIndexer) Height() uint64                { return f.height }
+func (f *fakeIndexer) Range(pageSize int, startCtxID, endCtxID *common.Hash) []*cc.CrossTransactionWithSignatures {
+	f.lastFromCtxID = startCtxID
+	return f.rangeResult
+}
+func (f *fakeIndexer) RangeByNumber(begin, end uint64, pageSize int) []*cc.CrossTransactionWithSignatures {
+	return nil
+}
+func (f *fakeIndexer) Query(pageSize int, startPage int, orderBy []db.FieldName, reverse bool, filter ...q.Matcher) []*cc.CrossTransactionWithSignatures {
+	f.lastOrderBy = orderBy
+	return f.queryResult
+}
+func (f *fakeIndexer) MarkPending(ctxId common.Hash) error   { return nil }
+func (f *fakeIndexer) MarkIllegal(ctxId common.Hash) error   { return nil }
+func (f *fakeIndexer) MarkExecuting(ctxId common.Hash) error { return nil }
+func (f *fakeIndexer) MarkCompleted(ctxId, execTxHash common.Hash, blockNum uint64) error {
+	return nil
+}
+func (f *fakeIndexer) MarkFinished(ctxId common.Hash) error { return nil }
+func (f *fakeIndexer) RangeByStatus(status db.CtxStatus, pageSize, startPage int) []*cc.CrossTransactionWithSignatures {
+	return f.rangeByStatus
+}
+func (f *fakeIndexer) SubscribeStatusChange(ch chan<- db.StatusEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+func (f *fakeIndexer) SetAssetID(ctxId, assetID common.Hash) error     { return nil }
+func (f *fakeIndexer) AssetIDOf(ctxId common.Hash) (common.Hash, bool) { return common.Hash{}, false }
+func (f *fakeIndexer) StatusOf(ctxId common.Hash) (db.CtxStatus, bool) { return 0, false }
+func (f *fakeIndexer) CompletionOf(ctxId common.Hash) (common.Hash, uint64, bool) {
+	return common.Hash{}, 0, false
+}
+func (f *fakeIndexer) Load() error   { return nil }
+func (f *fakeIndexer) Repair() error { return nil }
+func (f *fakeIndexer) Clean() error  { return nil }
+func (f *fakeIndexer) Close() error  { return nil }
+
+func TestHandleQueryRejectsUnknownOrderBy(t *testing.T) {
+	s := NewServer(":0", &fakeIndexer{})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/ctx?orderBy=1%20OR%201%3D1", nil)
+	rec := httptest.NewRecorder()
+	s.handleQuery(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleQueryFromDrivesRange(t *testing.T) {
+	indexer := &fakeIndexer{has: true}
+	s := NewServer(":0", indexer)
+
+	ctxID := common.HexToHash("0x01")
+	req := httptest.NewRequest(http.MethodGet, "/v1/ctx?from="+ctxID.Hex(), nil)
+	rec := httptest.NewRecorder()
+	s.handleQuery(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if indexer.lastFromCtxID == nil || *indexer.lastFromCtxID != ctxID {
+		t.Fatal("handleQuery with a from cursor didn't call Range with it")
+	}
+}
+
+func TestHandleQueryFromReorgedReturnsConflict(t *testing.T) {
+	indexer := &fakeIndexer{has: false, height: 42}
+	s := NewServer(":0", indexer)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/ctx?from="+common.HexToHash("0x01").Hex(), nil)
+	rec := httptest.NewRecorder()
+	s.handleQuery(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	var body reorgResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Cursor != "" {
+		t.Fatalf("cursor = %q, want empty: a block height isn't a resumable ctx PK and would send the client into a retry loop", body.Cursor)
+	}
+}
@@ -0,0 +1,22 @@
+package httpapi
+
+import (
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/metrics"
+)
+
+// requestCounter returns the per-endpoint, per-outcome request counter,
+// e.g. cross/httpapi/ctx/ok, cross/httpapi/ctx/reorg.
+func requestCounter(endpoint, outcome string) metrics.Counter {
+	return metrics.GetOrRegisterCounter("cross/httpapi/"+endpoint+"/"+outcome, nil)
+}
+
+// requestTimer returns the per-endpoint request latency timer.
+func requestTimer(endpoint string) metrics.Timer {
+	return metrics.GetOrRegisterTimer("cross/httpapi/"+endpoint+"/duration", nil)
+}
+
+func requestStart() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,50 @@
+// Package httpapi exposes a chain's CtxIndexer as a versioned REST API and
+// SSE stream, so dashboards can watch cross-transaction status without
+// querying BoltDB/SQL directly.
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/cross/database"
+	"github.com/simplechain-org/go-simplechain/log"
+)
+
+// Server serves the /v1/ctx REST API and SSE stream for a single chain's
+// CtxIndexer.
+type Server struct {
+	indexer db.CtxIndexer
+	http    *http.Server
+}
+
+// NewServer builds a Server serving addr on behalf of indexer. Call
+// ListenAndServe to start it.
+func NewServer(addr string, indexer db.CtxIndexer) *Server {
+	s := &Server{indexer: indexer}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/ctx", s.handleQuery)
+	mux.HandleFunc("/v1/ctx/stream", s.handleStream)
+
+	s.http = &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 0, // the SSE stream is long-lived
+	}
+	return s
+}
+
+// ListenAndServe starts serving until the server is closed.
+func (s *Server) ListenAndServe() error {
+	log.Info("Cross httpapi listening", "addr", s.http.Addr)
+	return s.http.ListenAndServe()
+}
+
+// Close shuts the server down, waiting for in-flight requests (including
+// open SSE streams) to finish or ctx to expire.
+func (s *Server) Close(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
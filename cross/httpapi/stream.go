@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/simplechain-org/go-simplechain/cross/database"
+)
+
+// handleStream serves GET /v1/ctx/stream, a Server-Sent Events feed of
+// every cross-transaction status change, backed by the indexer's
+// SubscribeStatusChange so clients don't have to poll /v1/ctx.
+//
+// SubscribeStatusChange's channel is fed straight from the indexer's shared
+// event.Feed, and Feed.Send blocks until every subscriber has received the
+// event. A slow or stalled SSE client sitting directly on that channel would
+// therefore stall every Mark* call across both chains' keepers, not just its
+// own stream. A relay goroutine drains the feed immediately into a small
+// per-client buffer and drops events instead of blocking when that buffer
+// is full, so this handler can never be the thing that slows down Mark*.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	feed := make(chan db.StatusEvent, 128)
+	sub := s.indexer.SubscribeStatusChange(feed)
+	defer sub.Unsubscribe()
+
+	client := make(chan db.StatusEvent, 16)
+	done := make(chan struct{})
+	defer close(done)
+	go relayStatusEvents(feed, client, done)
+
+	requestCounter("ctx_stream", "connect").Inc(1)
+
+	for {
+		select {
+		case ev := <-client:
+			fmt.Fprintf(w, "data: {\"ctxId\":%q,\"status\":%q}\n\n", ev.CtxId.String(), ev.Status.String())
+			flusher.Flush()
+		case err := <-sub.Err():
+			if err != nil {
+				requestCounter("ctx_stream", "error").Inc(1)
+			}
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// relayStatusEvents forwards feed onto client without ever blocking the
+// send from feed: client is written to with a non-blocking select, dropping
+// the event (and counting it) if the handler loop hasn't kept up.
+func relayStatusEvents(feed <-chan db.StatusEvent, client chan<- db.StatusEvent, done <-chan struct{}) {
+	for {
+		select {
+		case ev := <-feed:
+			select {
+			case client <- ev:
+			default:
+				requestCounter("ctx_stream", "dropped").Inc(1)
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package synchron
+
+import "math/big"
+
+// Config configures a single keeper's connection to its remote chain.
+type Config struct {
+	ChainID     *big.Int // chain ID of the remote chain this keeper watches
+	RemoteURL   string   // RPC endpoint of the remote chain
+	SyncSeconds uint64   // poll interval, in seconds
+	Confirms    uint64   // number of confirmations before a block is final; the keepers do not read it and sync each block as soon as it is fetched
+	StartNumber uint64   // block number to start syncing from when no cursor is persisted
+}
@@ -0,0 +1,374 @@
+// Package synchron keeps a chain's cross-transaction stores in sync with a
+// remote chain's cross-chain events: a MainchainKeeper watches the mainchain
+// for deposits and feeds them into the sidechain's ctxStore, while a
+// SidechainKeeper watches the sidechain for withdrawal-signed events and
+// feeds them into the mainchain's rtxStore.
+//
+// Both keepers are non-functional until the bridge contract ABI bindings
+// land: decodeDepositLog/decodeWithdrawLog and depositTopic/withdrawTopic
+// are skeletons (see errUnimplementedDecode). While depositTopic/
+// withdrawTopic are the zero hash, sync returns errUnimplementedDecode every
+// tick without advancing the per-chain cursor.
+package synchron
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/cross"
+	"github.com/simplechain-org/go-simplechain/cross/assets"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/cross/database"
+	"github.com/simplechain-org/go-simplechain/cross/synchron/service"
+	"github.com/simplechain-org/go-simplechain/log"
+)
+
+// errUnimplementedDecode is returned by decodeDepositLog/decodeWithdrawLog,
+// which are still skeletons: decoding a raw log into a CrossTransaction/
+// ReceptTransaction needs the bridge contract's ABI bindings, which don't
+// exist yet. Both keepers build and run against those stubs today, so every
+// handleDeposit/handleWithdraw call fails until the bindings land.
+var errUnimplementedDecode = errors.New("synchron: log decoding requires the bridge contract ABI bindings")
+
+// ctxStore is the subset of cross.ctxStore the keeper needs to feed
+// mainchain deposits into the sidechain's cross-transaction pool. ValidateCtx
+// is the pool's own admission check, the same one every other submission
+// path runs through, so the keeper defers to it rather than only relying on
+// its own asset-registry check below.
+type ctxStore interface {
+	AddRemote(*types.CrossTransaction) error
+	AddLocal(*types.CrossTransaction) error
+	ValidateCtx(*types.CrossTransaction) error
+}
+
+// rtxStore is the subset of cross.rtxStore the keeper needs to feed
+// sidechain withdrawal receipts into the mainchain's receipt pool.
+type rtxStore interface {
+	AddRemote(*types.ReceptTransaction) error
+	AddLocal(*types.ReceptTransaction) error
+	ValidateRtx(*types.ReceptTransaction) error
+}
+
+// cursorDB persists the per-chain "last synced block" cursor, the
+// ctxId -> AssetID tag set by the asset-registry check, the indexed
+// rows a reorg needs invalidated, and the Mark* status transitions a
+// keeper observes, implemented by the storm-backed db indexDB (see cross/database/cursor.go,
+// cross/database/asset.go, cross/database/mark.go).
+type cursorDB interface {
+	LastSyncCursor(chainID uint64) (*db.SyncCursor, error)
+	UpdateSyncCursor(chainID, number uint64, hash common.Hash) error
+	RollbackSyncCursor(chainID, number uint64, hash common.Hash) error
+	SetAssetID(ctxId, assetID common.Hash) error
+	MarkCompleted(ctxId, execTxHash common.Hash, blockNum uint64) error
+	RangeByNumber(begin, end uint64, pageSize int) []*cc.CrossTransactionWithSignatures
+	MarkIllegal(ctxId common.Hash) error
+}
+
+// chainReader is the subset of *service.Node the keeper needs to follow the
+// remote chain, extracted so advance/sync can run against a fake in tests
+// instead of dialing a real RPC endpoint.
+type chainReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, q types.FilterQuery) ([]types.Log, error)
+}
+
+// keeper holds the state shared by MainchainKeeper and SidechainKeeper: the
+// config (including the poll interval), the remote chain RPC client, the
+// synced-block cursor and the asset registry.
+type keeper struct {
+	config *Config
+	remote chainReader
+	cursor cursorDB
+	assets *assets.AssetStore
+
+	quit chan struct{}
+}
+
+func newKeeper(config *Config, remote chainReader, cursor cursorDB, assetStore *assets.AssetStore) keeper {
+	return keeper{config: config, remote: remote, cursor: cursor, assets: assetStore, quit: make(chan struct{})}
+}
+
+// Stop terminates the keeper's sync loop.
+func (k *keeper) Stop() {
+	close(k.quit)
+}
+
+// advance fetches the next unsynced block, detecting a reorg by comparing
+// its parent hash against the last synced block's hash. On a reorg it rolls
+// the cursor back to the parent so the caller resyncs the discarded range.
+func (k *keeper) advance(ctx context.Context) (block *types.Block, reorg bool, err error) {
+	last, err := k.cursor.LastSyncCursor(k.config.ChainID.Uint64())
+	if err != nil {
+		return nil, false, err
+	}
+
+	var (
+		nextNum  = new(big.Int).SetUint64(k.config.StartNumber)
+		lastHash common.Hash
+	)
+	if last != nil {
+		nextNum = new(big.Int).SetUint64(last.BlockNumber + 1)
+		lastHash = last.BlockHash
+	}
+
+	block, err = k.remote.BlockByNumber(ctx, nextNum)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if last != nil && block.ParentHash() != lastHash {
+		// parent-hash mismatch: the remote chain has reorged behind our
+		// cursor. Everything indexed from the reconfirmed parent through our
+		// old tip may belong to the discarded fork, so invalidate it before
+		// rolling the cursor back and letting the caller resync that range.
+		parent, err := k.remote.HeaderByNumber(ctx, new(big.Int).Sub(nextNum, big.NewInt(2)))
+		if err != nil {
+			return nil, false, err
+		}
+		if err := k.invalidateOrphaned(parent.Number.Uint64()+1, last.BlockNumber); err != nil {
+			return nil, false, err
+		}
+		return nil, true, k.cursor.RollbackSyncCursor(k.config.ChainID.Uint64(), parent.Number.Uint64(), parent.Hash())
+	}
+
+	return block, false, nil
+}
+
+// invalidateOrphaned marks every cross transaction indexed in the
+// now-discarded block range [begin, end] as CtxStatusIllegal, so a reorg
+// doesn't leave rows behind for a cross transaction whose block no longer
+// exists on the chain that won.
+func (k *keeper) invalidateOrphaned(begin, end uint64) error {
+	// a negative pageSize means no limit; storm.Limit(0) would return no rows.
+	for _, orphan := range k.cursor.RangeByNumber(begin, end, -1) {
+		if err := k.cursor.MarkIllegal(orphan.ID()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k *keeper) commit(block *types.Block) error {
+	return k.cursor.UpdateSyncCursor(k.config.ChainID.Uint64(), block.NumberU64(), block.Hash())
+}
+
+// MainchainKeeper watches a mainchain for cross-chain deposit events and
+// relays them into the local sidechain's ctxStore.
+type MainchainKeeper struct {
+	keeper
+	store ctxStore
+}
+
+// NewMainchainKeeper creates a keeper that syncs mainchain deposits into store.
+func NewMainchainKeeper(config *Config, remote *service.Node, cursor cursorDB, assetStore *assets.AssetStore, store ctxStore) *MainchainKeeper {
+	return &MainchainKeeper{keeper: newKeeper(config, remote, cursor, assetStore), store: store}
+}
+
+// Start begins polling the mainchain every config.SyncSeconds.
+func (k *MainchainKeeper) Start() {
+	go k.loop()
+}
+
+func (k *MainchainKeeper) loop() {
+	ticker := time.NewTicker(time.Duration(k.config.SyncSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := k.sync(); err != nil {
+				log.Warn("MainchainKeeper sync failed", "chainID", k.config.ChainID, "err", err)
+			}
+		case <-k.quit:
+			return
+		}
+	}
+}
+
+func (k *MainchainKeeper) sync() error {
+	if depositTopic == (common.Hash{}) {
+		// depositTopic is only ever the zero hash before the bridge contract
+		// ABI bindings land; filtering on it would never match a real log,
+		// so advancing the cursor past it would silently "sync" nothing.
+		// Fail loudly every tick instead of looking like a healthy keeper.
+		return errUnimplementedDecode
+	}
+
+	ctx := context.Background()
+	block, reorg, err := k.advance(ctx)
+	if err != nil || reorg {
+		return err
+	}
+
+	logs, err := k.remote.FilterLogs(ctx, types.FilterQuery{
+		BlockHash: blockHashPtr(block.Hash()),
+		Topics:    [][]common.Hash{{depositTopic}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, l := range logs {
+		if err := k.handleDeposit(l); err != nil {
+			return err
+		}
+	}
+	return k.commit(block)
+}
+
+// handleDeposit decodes a mainchain deposit log into a CrossTransaction and
+// adds it to the sidechain's ctxStore, rejecting token pairs that haven't
+// been registered in the asset registry. ValidateCtx runs first since it's
+// the store's own admission check; the asset-registry check only covers
+// this keeper's submission path, so it can't substitute for ValidateCtx on
+// its own.
+func (k *MainchainKeeper) handleDeposit(l types.Log) error {
+	ctx, err := decodeDepositLog(l)
+	if err != nil {
+		return err
+	}
+	if err := k.store.ValidateCtx(ctx); err != nil {
+		return err
+	}
+
+	sourceChainID, sourceAsset := depositAsset(ctx)
+	asset, err := k.assets.Get(sourceChainID, sourceAsset)
+	if err != nil || asset.Disabled {
+		return cross.ErrUnknownAsset{SourceChainID: sourceChainID, SourceAsset: sourceAsset.String()}
+	}
+	if err := k.store.AddRemote(ctx); err != nil {
+		return err
+	}
+	return k.cursor.SetAssetID(ctx.ID(), asset.AssetID)
+}
+
+// SidechainKeeper watches a sidechain for withdrawal-signed events and
+// relays them into the local mainchain's rtxStore.
+type SidechainKeeper struct {
+	keeper
+	store rtxStore
+}
+
+// NewSidechainKeeper creates a keeper that syncs sidechain withdrawal
+// receipts into store.
+func NewSidechainKeeper(config *Config, remote *service.Node, cursor cursorDB, assetStore *assets.AssetStore, store rtxStore) *SidechainKeeper {
+	return &SidechainKeeper{keeper: newKeeper(config, remote, cursor, assetStore), store: store}
+}
+
+// Start begins polling the sidechain every config.SyncSeconds.
+func (k *SidechainKeeper) Start() {
+	go k.loop()
+}
+
+func (k *SidechainKeeper) loop() {
+	ticker := time.NewTicker(time.Duration(k.config.SyncSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := k.sync(); err != nil {
+				log.Warn("SidechainKeeper sync failed", "chainID", k.config.ChainID, "err", err)
+			}
+		case <-k.quit:
+			return
+		}
+	}
+}
+
+func (k *SidechainKeeper) sync() error {
+	if withdrawTopic == (common.Hash{}) {
+		// see the matching check in MainchainKeeper.sync.
+		return errUnimplementedDecode
+	}
+
+	ctx := context.Background()
+	block, reorg, err := k.advance(ctx)
+	if err != nil || reorg {
+		return err
+	}
+
+	logs, err := k.remote.FilterLogs(ctx, types.FilterQuery{
+		BlockHash: blockHashPtr(block.Hash()),
+		Topics:    [][]common.Hash{{withdrawTopic}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, l := range logs {
+		if err := k.handleWithdraw(l); err != nil {
+			return err
+		}
+	}
+	return k.commit(block)
+}
+
+// handleWithdraw decodes a sidechain withdrawal-signed log into a
+// ReceptTransaction and adds it to the mainchain's rtxStore, rejecting
+// token pairs that haven't been registered in the asset registry. See
+// handleDeposit for why ValidateRtx runs first.
+//
+// This is the destination-chain side of a cross transaction's lifecycle:
+// the log being handled here is the matching receipt MarkCompleted's doc
+// comment refers to, so this is what actually drives a ctx out of
+// CtxStatusPending once its withdrawal is observed.
+func (k *SidechainKeeper) handleWithdraw(l types.Log) error {
+	rtx, err := decodeWithdrawLog(l)
+	if err != nil {
+		return err
+	}
+	if err := k.store.ValidateRtx(rtx); err != nil {
+		return err
+	}
+
+	sourceChainID, sourceAsset := withdrawAsset(rtx)
+	asset, err := k.assets.Get(sourceChainID, sourceAsset)
+	if err != nil || asset.Disabled {
+		return cross.ErrUnknownAsset{SourceChainID: sourceChainID, SourceAsset: sourceAsset.String()}
+	}
+	if err := k.store.AddRemote(rtx); err != nil {
+		return err
+	}
+	if err := k.cursor.SetAssetID(rtx.CTxId, asset.AssetID); err != nil {
+		return err
+	}
+	return k.cursor.MarkCompleted(rtx.CTxId, l.TxHash, l.BlockNumber)
+}
+
+// depositTopic and withdrawTopic identify the bridge contract events a
+// deposit / withdrawal-signed log must match; populated once the bridge
+// contract ABI bindings land alongside this package.
+var (
+	depositTopic  common.Hash
+	withdrawTopic common.Hash
+)
+
+// decodeDepositLog is a skeleton: it always fails until the bridge contract
+// ABI bindings exist to actually decode l's data into a CrossTransaction.
+func decodeDepositLog(l types.Log) (*types.CrossTransaction, error) {
+	return nil, errUnimplementedDecode
+}
+
+// decodeWithdrawLog is a skeleton; see decodeDepositLog.
+func decodeWithdrawLog(l types.Log) (*types.ReceptTransaction, error) {
+	return nil, errUnimplementedDecode
+}
+
+// depositAsset and withdrawAsset extract the source chain/asset a decoded
+// event refers to, for the asset-registry check in handleDeposit/
+// handleWithdraw. They're unreachable skeletons today: decodeDepositLog/
+// decodeWithdrawLog always error first, so these never run against a real
+// log until the bridge contract ABI bindings land alongside them.
+func depositAsset(ctx *types.CrossTransaction) (uint64, common.Address) {
+	return 0, common.Address{}
+}
+
+func withdrawAsset(rtx *types.ReceptTransaction) (uint64, common.Address) {
+	return 0, common.Address{}
+}
+
+func blockHashPtr(h common.Hash) *common.Hash { return &h }
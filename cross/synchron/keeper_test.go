@@ -0,0 +1,147 @@
+package synchron
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	db "github.com/simplechain-org/go-simplechain/cross/database"
+)
+
+// fakeChainReader serves blocks/headers from a fixed height->value map, so
+// advance's reorg detection can be driven without a real RPC endpoint.
+type fakeChainReader struct {
+	blocks  map[uint64]*types.Block
+	headers map[uint64]*types.Header
+}
+
+func (f *fakeChainReader) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	block, ok := f.blocks[number.Uint64()]
+	if !ok {
+		return nil, errors.New("fakeChainReader: no block at that height")
+	}
+	return block, nil
+}
+
+func (f *fakeChainReader) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := f.headers[number.Uint64()]
+	if !ok {
+		return nil, errors.New("fakeChainReader: no header at that height")
+	}
+	return header, nil
+}
+
+func (f *fakeChainReader) FilterLogs(ctx context.Context, q types.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+// fakeCursorDB is a cursorDB recording what advance asked it to do, so tests
+// can assert on rollback/invalidation behavior without a real indexDB.
+type fakeCursorDB struct {
+	last *db.SyncCursor
+
+	rolledBackTo                   *db.SyncCursor
+	invalidatedFrom, invalidatedTo uint64
+	invalidateCalled               bool
+}
+
+func (f *fakeCursorDB) LastSyncCursor(chainID uint64) (*db.SyncCursor, error) { return f.last, nil }
+
+func (f *fakeCursorDB) UpdateSyncCursor(chainID, number uint64, hash common.Hash) error {
+	return nil
+}
+
+func (f *fakeCursorDB) RollbackSyncCursor(chainID, number uint64, hash common.Hash) error {
+	f.rolledBackTo = &db.SyncCursor{ChainID: chainID, BlockNumber: number, BlockHash: hash}
+	return nil
+}
+
+func (f *fakeCursorDB) SetAssetID(ctxId, assetID common.Hash) error { return nil }
+
+func (f *fakeCursorDB) MarkCompleted(ctxId, execTxHash common.Hash, blockNum uint64) error {
+	return nil
+}
+
+// RangeByNumber returns no rows: cc.CrossTransactionWithSignatures is
+// defined outside this snapshot, so there's no safe way to construct one
+// here to exercise invalidateOrphaned's MarkIllegal loop body. The range
+// bounds it was called with are still recorded and asserted on below.
+func (f *fakeCursorDB) RangeByNumber(begin, end uint64, pageSize int) []*cc.CrossTransactionWithSignatures {
+	f.invalidateCalled = true
+	f.invalidatedFrom, f.invalidatedTo = begin, end
+	return nil
+}
+
+func (f *fakeCursorDB) MarkIllegal(ctxId common.Hash) error { return nil }
+
+func testConfig() *Config {
+	return &Config{ChainID: big.NewInt(1), StartNumber: 10, SyncSeconds: 1, Confirms: 0}
+}
+
+func TestAdvanceNoReorg(t *testing.T) {
+	lastHash := common.HexToHash("0x02")
+
+	last := &db.SyncCursor{ChainID: 1, BlockNumber: 10, BlockHash: lastHash}
+	header := &types.Header{Number: big.NewInt(11), ParentHash: lastHash}
+	block := types.NewBlockWithHeader(header)
+
+	cursor := &fakeCursorDB{last: last}
+	remote := &fakeChainReader{blocks: map[uint64]*types.Block{11: block}}
+
+	k := newKeeper(testConfig(), remote, cursor, nil)
+	got, reorg, err := k.advance(context.Background())
+	if err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if reorg {
+		t.Fatal("advance reported a reorg on a matching parent hash")
+	}
+	if got.Hash() != block.Hash() {
+		t.Fatalf("advance returned the wrong block")
+	}
+	if cursor.invalidateCalled {
+		t.Fatal("advance invalidated rows on the non-reorg path")
+	}
+}
+
+func TestAdvanceReorgRollsBackAndInvalidates(t *testing.T) {
+	newParentHash := common.HexToHash("0x99")
+	lastHash := common.HexToHash("0x02") // the now-orphaned tip we'd synced
+
+	last := &db.SyncCursor{ChainID: 1, BlockNumber: 10, BlockHash: lastHash}
+	// the remote's block 11 now has a different parent than our cursor
+	header := &types.Header{Number: big.NewInt(11), ParentHash: newParentHash}
+	block := types.NewBlockWithHeader(header)
+	parentHeader := &types.Header{Number: big.NewInt(9)}
+
+	cursor := &fakeCursorDB{last: last}
+	remote := &fakeChainReader{
+		blocks:  map[uint64]*types.Block{11: block},
+		headers: map[uint64]*types.Header{9: parentHeader},
+	}
+
+	k := newKeeper(testConfig(), remote, cursor, nil)
+	got, reorg, err := k.advance(context.Background())
+	if err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if !reorg {
+		t.Fatal("advance missed a parent-hash mismatch")
+	}
+	if got != nil {
+		t.Fatal("advance returned a block on the reorg path")
+	}
+	if !cursor.invalidateCalled {
+		t.Fatal("advance never invalidated the orphaned range")
+	}
+	if cursor.invalidatedFrom != 10 || cursor.invalidatedTo != 10 {
+		t.Fatalf("invalidated range = [%d, %d], want [10, 10]", cursor.invalidatedFrom, cursor.invalidatedTo)
+	}
+	if cursor.rolledBackTo == nil || cursor.rolledBackTo.BlockNumber != 9 {
+		t.Fatalf("rolled back to %+v, want block 9", cursor.rolledBackTo)
+	}
+}
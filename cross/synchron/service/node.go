@@ -0,0 +1,83 @@
+// Package service provides a thin read-only RPC client to a remote
+// simplechain node. It is used by the cross-chain synchron keepers to poll
+// blocks and logs without depending on the remote chain's internal packages.
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/rpc"
+)
+
+// Node is a minimal RPC client to a remote chain, scoped to the handful of
+// calls the synchron keepers need to track cross-chain events.
+type Node struct {
+	client *rpc.Client
+}
+
+// Dial connects to the remote chain's RPC endpoint (http, ws or ipc).
+func Dial(rawurl string) (*Node, error) {
+	client, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{client: client}, nil
+}
+
+// Close tears down the underlying RPC client.
+func (n *Node) Close() {
+	n.client.Close()
+}
+
+// HeaderByNumber returns the header of the given height, or the latest
+// header if number is nil.
+func (n *Node) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var head *types.Header
+	err := n.client.CallContext(ctx, &head, "eth_getBlockByNumber", toBlockNumArg(number), false)
+	if err == nil && head == nil {
+		err = rpc.ErrNoResult
+	}
+	return head, err
+}
+
+// BlockByNumber returns the full block at the given height, or the latest
+// block if number is nil.
+func (n *Node) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	var block *types.Block
+	err := n.client.CallContext(ctx, &block, "eth_getBlockByNumber", toBlockNumArg(number), true)
+	if err == nil && block == nil {
+		err = rpc.ErrNoResult
+	}
+	return block, err
+}
+
+// FilterLogs returns the logs matching the given filter query, used to pick
+// out cross-chain deposit/withdrawal-signed events from a synced block.
+func (n *Node) FilterLogs(ctx context.Context, q types.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	err := n.client.CallContext(ctx, &logs, "eth_getLogs", toFilterArg(q))
+	return logs, err
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return rpc.BlockNumber(number.Int64()).String()
+}
+
+func toFilterArg(q types.FilterQuery) interface{} {
+	arg := map[string]interface{}{
+		"address": q.Addresses,
+		"topics":  q.Topics,
+	}
+	if q.BlockHash != nil {
+		arg["blockHash"] = *q.BlockHash
+	} else {
+		arg["fromBlock"] = toBlockNumArg(q.FromBlock)
+		arg["toBlock"] = toBlockNumArg(q.ToBlock)
+	}
+	return arg
+}